@@ -0,0 +1,155 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// HealthCheck reads and decodes one key out of a service:category's health
+// pool. Name is also the key under which the decoded value is reported in
+// HealthData.Checks.
+type HealthCheck interface {
+	Name() string
+	RedisKey(service, category string) string
+	Decode(val string) (any, error)
+}
+
+// Factory builds a HealthCheck from its config Module. Built-in checks are
+// registered in init() below; a JSON config can declare additional checks
+// of any registered Type without recompiling.
+type Factory func(mod Module) (HealthCheck, error)
+
+// Module is one entry in the config file's "checks" list. Type selects the
+// Factory, Key is the health-pool key suffix to read and also the check's
+// reported name. A config's "checks" list
+// replaces the built-in modules wholesale, so enabling/disabling/reordering
+// the defaults means re-listing them explicitly.
+type Module struct {
+	Type    string `json:"type"`
+	Key     string `json:"key"`
+	Enabled *bool  `json:"enabled,omitempty"`
+}
+
+func (m Module) enabled() bool {
+	return m.Enabled == nil || *m.Enabled
+}
+
+var registry = make(map[string]Factory)
+
+// Register adds a check factory to the global registry under name, so
+// config files can reference it via Module.Type.
+func Register(name string, factory Factory) {
+	registry[name] = factory
+}
+
+func init() {
+	Register("int", func(mod Module) (HealthCheck, error) { return intCheck{key: mod.Key}, nil })
+	Register("gauge", func(mod Module) (HealthCheck, error) { return gaugeCheck{key: mod.Key}, nil })
+	Register("bool", func(mod Module) (HealthCheck, error) { return boolCheck{key: mod.Key}, nil })
+	Register("string", func(mod Module) (HealthCheck, error) { return stringCheck{key: mod.Key}, nil })
+}
+
+// defaultChecks are the 8 health-pool keys jettison_health has always read,
+// shipped as built-in modules so a config file with no "checks" section
+// reads the same keys as before (reported under HealthData.Checks).
+var defaultChecks = []Module{
+	{Type: "int", Key: "beats"},
+	{Type: "int", Key: "cap"},
+	{Type: "int", Key: "depletion_rate"},
+	{Type: "int", Key: "init"},
+	{Type: "int", Key: "replenish_rate"},
+	{Type: "int", Key: "running"},
+	{Type: "int", Key: "exit"},
+	{Type: "int", Key: "health"},
+}
+
+// buildChecks resolves a config's "checks" list (or defaultChecks, if the
+// config doesn't declare one) into the HealthCheck instances fetchHealthData
+// will query.
+func buildChecks(config *Config) ([]HealthCheck, error) {
+	modules := defaultChecks
+	if len(config.Checks) > 0 {
+		modules = config.Checks
+	}
+
+	var checks []HealthCheck
+	for _, mod := range modules {
+		if !mod.enabled() {
+			continue
+		}
+
+		factory, ok := registry[mod.Type]
+		if !ok {
+			return nil, fmt.Errorf("unknown check type %q for key %q", mod.Type, mod.Key)
+		}
+
+		check, err := factory(mod)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build check %q: %w", mod.Key, err)
+		}
+		checks = append(checks, check)
+	}
+
+	return checks, nil
+}
+
+// healthPoolKey returns the "<service>:__healthpool__<category>_<key>"
+// pattern shared by every built-in and config-declared check.
+func healthPoolKey(service, category, key string) string {
+	return fmt.Sprintf("%s:__healthpool__%s_%s", service, category, key)
+}
+
+// intCheck decodes an integer-valued health-pool key. This is the type
+// behind all 8 built-in checks (beats, cap, depletion_rate, ...).
+type intCheck struct{ key string }
+
+func (c intCheck) Name() string { return c.key }
+func (c intCheck) RedisKey(service, category string) string {
+	return healthPoolKey(service, category, c.key)
+}
+func (c intCheck) Decode(val string) (any, error) { return strconv.Atoi(val) }
+
+// gaugeCheck decodes a floating point health-pool key, e.g. a
+// config-declared "latency_ms" gauge.
+type gaugeCheck struct{ key string }
+
+func (c gaugeCheck) Name() string { return c.key }
+func (c gaugeCheck) RedisKey(service, category string) string {
+	return healthPoolKey(service, category, c.key)
+}
+func (c gaugeCheck) Decode(val string) (any, error) { return strconv.ParseFloat(val, 64) }
+
+// boolCheck decodes a "0"/"1" health-pool key into a boolean, e.g. a
+// config-declared "degraded" flag.
+type boolCheck struct{ key string }
+
+func (c boolCheck) Name() string { return c.key }
+func (c boolCheck) RedisKey(service, category string) string {
+	return healthPoolKey(service, category, c.key)
+}
+func (c boolCheck) Decode(val string) (any, error) {
+	n, err := strconv.Atoi(val)
+	if err != nil {
+		return nil, err
+	}
+	return n != 0, nil
+}
+
+// stringCheck passes a health-pool key's value through unchanged, e.g. a
+// config-declared "last_error" message.
+type stringCheck struct{ key string }
+
+func (c stringCheck) Name() string { return c.key }
+func (c stringCheck) RedisKey(service, category string) string {
+	return healthPoolKey(service, category, c.key)
+}
+func (c stringCheck) Decode(val string) (any, error) { return val, nil }
+
+// marshalCheckValue encodes a decoded check value for HealthData.Checks.
+// Decode implementations only ever return JSON-marshalable types
+// (int, float64, bool, string), so a marshal error here would indicate a
+// bug in a HealthCheck implementation rather than bad input.
+func marshalCheckValue(v any) (json.RawMessage, error) {
+	return json.Marshal(v)
+}
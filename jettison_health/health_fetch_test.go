@@ -0,0 +1,134 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+)
+
+// newTestServer starts a miniredis instance selected to redisDB, since
+// buildRedisClient connects standalone clients on redisDB; fixtures
+// must be seeded there rather than on miniredis' default DB 0.
+func newTestServer(t *testing.T) *miniredis.Miniredis {
+	t.Helper()
+	s, err := miniredis.Run()
+	if err != nil {
+		t.Fatalf("failed to start miniredis: %v", err)
+	}
+	t.Cleanup(s.Close)
+	s.Select(redisDB)
+	return s
+}
+
+func TestFetchHealthDataAgainstMiniredis(t *testing.T) {
+	s := newTestServer(t)
+
+	target := ServiceCategory{Service: "jettison", Category: "ingest"}
+	checks, err := buildChecks(&Config{})
+	if err != nil {
+		t.Fatalf("buildChecks() error = %v", err)
+	}
+
+	values := map[string]string{
+		"beats":          "12345",
+		"cap":            "10",
+		"depletion_rate": "1",
+		"init":           "1",
+		"replenish_rate": "1",
+		"running":        "1",
+		"exit":           "0",
+		"health":         "1",
+	}
+	for key, val := range values {
+		s.Set(healthPoolKey(target.Service, target.Category, key), val)
+	}
+
+	client, err := buildRedisClient(&RedisConfig{Mode: redisModeStandalone, Addrs: []string{s.Addr()}}, "", "")
+	if err != nil {
+		t.Fatalf("buildRedisClient() error = %v", err)
+	}
+	defer client.Close()
+
+	data := fetchHealthData(context.Background(), client, checks, target.Service, target.Category)
+	if !data.Exists {
+		t.Fatalf("data.Exists = false, missing keys: %v", data.MissingKeys)
+	}
+	if len(data.MissingKeys) != 0 {
+		t.Errorf("MissingKeys = %v, want none", data.MissingKeys)
+	}
+
+	for key, want := range values {
+		var got int
+		if err := json.Unmarshal(data.Checks[key], &got); err != nil {
+			t.Fatalf("decoding %q: %v", key, err)
+		}
+		wantInt, _ := strconv.Atoi(want)
+		if got != wantInt {
+			t.Errorf("Checks[%q] = %d, want %d", key, got, wantInt)
+		}
+	}
+}
+
+func TestFetchHealthDataMissingKeys(t *testing.T) {
+	s := newTestServer(t)
+
+	target := ServiceCategory{Service: "jettison", Category: "ingest"}
+	checks, err := buildChecks(&Config{})
+	if err != nil {
+		t.Fatalf("buildChecks() error = %v", err)
+	}
+
+	// Only one of the eight built-in keys is present.
+	s.Set(healthPoolKey(target.Service, target.Category, "beats"), "1")
+
+	client, err := buildRedisClient(&RedisConfig{Mode: redisModeStandalone, Addrs: []string{s.Addr()}}, "", "")
+	if err != nil {
+		t.Fatalf("buildRedisClient() error = %v", err)
+	}
+	defer client.Close()
+
+	data := fetchHealthData(context.Background(), client, checks, target.Service, target.Category)
+	if data.Exists {
+		t.Error("data.Exists = true, want false with most keys missing")
+	}
+	if len(data.MissingKeys) != len(checks)-1 {
+		t.Errorf("len(MissingKeys) = %d, want %d", len(data.MissingKeys), len(checks)-1)
+	}
+}
+
+func TestFetchTargetsConcurrentAgainstMiniredis(t *testing.T) {
+	s := newTestServer(t)
+
+	targets := []ServiceCategory{
+		{Service: "svc-a", Category: "cat1"},
+		{Service: "svc-b", Category: "cat1"},
+	}
+	checks, err := buildChecks(&Config{})
+	if err != nil {
+		t.Fatalf("buildChecks() error = %v", err)
+	}
+	for _, target := range targets {
+		for _, check := range checks {
+			s.Set(check.RedisKey(target.Service, target.Category), "1")
+		}
+	}
+
+	client, err := buildRedisClient(&RedisConfig{Mode: redisModeStandalone, Addrs: []string{s.Addr()}}, "", "")
+	if err != nil {
+		t.Fatalf("buildRedisClient() error = %v", err)
+	}
+	defer client.Close()
+
+	results := fetchTargets(context.Background(), client, checks, targets, defaultConcurrency, false)
+	if len(results) != len(targets) {
+		t.Fatalf("len(results) = %d, want %d", len(results), len(targets))
+	}
+	for _, target := range targets {
+		if data := results[targetKey(target)]; !data.Exists {
+			t.Errorf("target %s: Exists = false, missing keys: %v", targetKey(target), data.MissingKeys)
+		}
+	}
+}
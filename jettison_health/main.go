@@ -6,38 +6,59 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"sort"
 	"strings"
+	"sync"
+	"syscall"
 	"time"
 
 	"github.com/redis/go-redis/v9"
 )
 
 const (
-	redisDB      = 2
-	redisTimeout = 5 * time.Second
+	redisDB = 2
+
+	// defaultRedisTimeout is the default value for --timeout.
+	defaultRedisTimeout = 5 * time.Second
+
+	// defaultConcurrency is the default value for --concurrency.
+	defaultConcurrency = 8
+
+	// keyspaceNotifyConfig is the Redis setting required for PSUBSCRIBE on
+	// keyspace events ("K") and keyevent events ("E") for all event classes ("A").
+	keyspaceNotifyConfig = "KEA"
 )
 
 // Config represents the application configuration
 type Config struct {
-	Redis struct {
-		Host       string `json:"host"`
-		Port       int    `json:"port"`
-		SecretsDir string `json:"secrets_dir"`
-	} `json:"redis"`
+	Redis RedisConfig `json:"redis"`
+	// Checks optionally overrides the default 8 built-in health-pool
+	// checks. See buildChecks.
+	Checks []Module `json:"checks,omitempty"`
+	// Serve configures the "serve" subcommand's Prometheus exporter.
+	Serve ServeConfig `json:"serve,omitempty"`
 }
 
+// ServeConfig configures the "serve" subcommand. Unlike the CLI/--watch
+// modes, its targets come from the config file rather than arguments, since
+// a scrape target list is operational configuration, not a one-off query.
+type ServeConfig struct {
+	ListenAddr     string            `json:"listen_addr,omitempty"`
+	ScrapeCacheTTL Duration          `json:"scrape_cache_ttl,omitempty"`
+	Targets        []ServiceCategory `json:"targets"`
+}
+
+// HealthData is one target's fetch result. Checks holds each resolved
+// HealthCheck's decoded value, keyed by check name.
 type HealthData struct {
-	Beats          *int `json:"beats,omitempty"`
-	Cap            *int `json:"cap,omitempty"`
-	DepletionRate  *int `json:"depletion_rate,omitempty"`
-	Init           *int `json:"init,omitempty"`
-	ReplenishRate  *int `json:"replenish_rate,omitempty"`
-	Running        *int `json:"running,omitempty"`
-	Exit           *int `json:"exit,omitempty"`
-	Health         *int `json:"health,omitempty"`
-	Exists         bool `json:"exists"`
-	MissingKeys    []string `json:"missing_keys,omitempty"`
+	Checks      map[string]json.RawMessage `json:"checks"`
+	Exists      bool                       `json:"exists"`
+	MissingKeys []string                   `json:"missing_keys,omitempty"`
+	// LatencyMS is the time this target's fetch took, in milliseconds.
+	// Only populated when --verbose is set.
+	LatencyMS *float64 `json:"latency_ms,omitempty"`
 }
 
 type ServiceCategory struct {
@@ -53,16 +74,72 @@ type ErrorResponse struct {
 
 type SuccessResponse struct {
 	Data map[string]HealthData `json:"data"`
+	// Aggregate summarizes Data against --require-all, --require-any,
+	// --min-healthy, and --threshold. Only populated when at least one of
+	// those flags is set.
+	Aggregate *Aggregate `json:"aggregate,omitempty"`
 }
 
+// Aggregate lets jettison_health act as a liveness/readiness gate for
+// init systems and orchestrators instead of a plain key dump: it reports
+// how many targets are healthy and which predicate first failed.
+type Aggregate struct {
+	HealthyCount   int      `json:"healthy_count"`
+	Total          int      `json:"total"`
+	FailedTargets  []string `json:"failed_targets,omitempty"`
+	FirstViolation string   `json:"first_violation,omitempty"`
+}
+
+// Exit codes used once any aggregation flag is set. Without one of those
+// flags, jettison_health keeps its original 0/1 (all present/some missing)
+// behavior for backward compatibility.
+const (
+	exitOK                 = 0
+	exitConnectionOrConfig = 1
+	exitDegraded           = 2
+	exitMissingKeys        = 3
+	exitThresholdViolation = 4
+)
+
 func main() {
+	// "serve" is a subcommand: jettison_health serve --config <config.json>
+	// runs the Prometheus exporter instead of the one-shot/--watch CLI.
+	if len(os.Args) > 1 && os.Args[1] == "serve" {
+		runServe(os.Args[2:])
+		return
+	}
+	runFetch(os.Args[1:])
+}
+
+// runFetch implements the original one-shot (and --watch) CLI: fetch the
+// service:category targets given on the command line and print their
+// health data as JSON.
+func runFetch(args []string) {
+	fs := flag.NewFlagSet("jettison_health", flag.ExitOnError)
+
 	// Parse command line flags
-	configPath := flag.String("config", "", "Path to configuration file")
-	flag.Parse()
+	configPath := fs.String("config", "", "Path to configuration file")
+	watch := fs.Bool("watch", false, "Keep running and stream health updates as they happen")
+	daemon := fs.Bool("daemon", false, "Alias for --watch")
+	interval := fs.Duration("interval", 0, "Also re-emit every target at this interval (e.g. 5s), covering setups without keyspace notifications; 0 disables polling")
+	concurrency := fs.Int("concurrency", defaultConcurrency, "Maximum number of targets fetched concurrently")
+	timeout := fs.Duration("timeout", defaultRedisTimeout, "Redis operation timeout (e.g. 5s)")
+	verbose := fs.Bool("verbose", false, "Include per-target fetch latency in the output")
+	requireAll := fs.Bool("require-all", false, "Exit non-zero (degraded) unless every target is healthy")
+	requireAny := fs.Bool("require-any", false, "Exit non-zero (degraded) unless at least one target is healthy")
+	minHealthy := fs.Int("min-healthy", 0, "Exit non-zero (degraded) unless at least N targets are healthy")
+	thresholdSpec := fs.String("threshold", "", "Comma-separated predicates evaluated against each target's checks, e.g. health>=1,running==1")
+	fs.Parse(args)
+
+	if *concurrency <= 0 {
+		*concurrency = defaultConcurrency
+	}
+
+	watchMode := *watch || *daemon
 
 	// Check for config flag
 	if *configPath == "" {
-		printError("Configuration required", "Usage: jettison_health --config <config.json> <service>:<category> [<service>:<category> ...]", flag.Args())
+		printError("Configuration required", "Usage: jettison_health --config <config.json> <service>:<category> [<service>:<category> ...]", fs.Args())
 		os.Exit(1)
 	}
 
@@ -74,18 +151,31 @@ func main() {
 	}
 
 	// Get remaining arguments (service:category pairs)
-	args := flag.Args()
-	if len(args) < 1 {
-		printError("No arguments provided", "Usage: jettison_health --config <config.json> <service>:<category> [<service>:<category> ...]", args)
+	targetArgs := fs.Args()
+	if len(targetArgs) < 1 {
+		printError("No arguments provided", "Usage: jettison_health --config <config.json> <service>:<category> [<service>:<category> ...]", targetArgs)
 		os.Exit(1)
 	}
 
 	// Parse service:category arguments
-	targets, err := parseArguments(args)
+	targets, err := parseArguments(targetArgs)
+	if err != nil {
+		printError("Invalid arguments", err.Error(), targetArgs)
+		os.Exit(1)
+	}
+
+	checks, err := buildChecks(config)
+	if err != nil {
+		printError("Invalid check configuration", err.Error(), nil)
+		os.Exit(1)
+	}
+
+	thresholds, err := parseThresholds(*thresholdSpec)
 	if err != nil {
-		printError("Invalid arguments", err.Error(), args)
+		printError("Invalid threshold", err.Error(), nil)
 		os.Exit(1)
 	}
+	aggregationRequested := *requireAll || *requireAny || *minHealthy > 0 || len(thresholds) > 0
 
 	// Load Redis credentials
 	username, password, err := loadRedisCredentials(config)
@@ -95,15 +185,14 @@ func main() {
 	}
 
 	// Connect to Redis
-	client := redis.NewClient(&redis.Options{
-		Addr:     fmt.Sprintf("%s:%d", config.Redis.Host, config.Redis.Port),
-		Username: username,
-		Password: password,
-		DB:       redisDB,
-	})
+	client, err := buildRedisClient(&config.Redis, username, password)
+	if err != nil {
+		printError("Redis client configuration failed", err.Error(), nil)
+		os.Exit(1)
+	}
 	defer client.Close()
 
-	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
 	defer cancel()
 
 	// Test connection
@@ -112,30 +201,200 @@ func main() {
 		os.Exit(1)
 	}
 
-	// Fetch health data for all targets
-	allExists := true
-	results := make(map[string]HealthData)
+	if watchMode {
+		runWatch(client, targets, checks, config.Redis.Mode, *interval)
+		return
+	}
 
-	for _, target := range targets {
-		key := fmt.Sprintf("%s:%s", target.Service, target.Category)
-		data := fetchHealthData(ctx, client, target.Service, target.Category)
-		results[key] = data
+	// Fetch health data for all targets, pipelined per target and bounded
+	// by --concurrency across targets.
+	results := fetchTargets(ctx, client, checks, targets, *concurrency, *verbose)
 
+	allExists := true
+	for _, data := range results {
 		if !data.Exists {
 			allExists = false
 		}
 	}
 
-	// Output JSON
-	response := SuccessResponse{Data: results}
+	if !aggregationRequested {
+		// Output JSON
+		response := SuccessResponse{Data: results}
+		output, _ := json.MarshalIndent(response, "", "  ")
+		fmt.Println(string(output))
+
+		// Exit code: 0 if all exist, 1 if any missing
+		if !allExists {
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
+	agg := aggregate(results, thresholds)
+
+	response := SuccessResponse{Data: results, Aggregate: agg}
 	output, _ := json.MarshalIndent(response, "", "  ")
 	fmt.Println(string(output))
 
-	// Exit code: 0 if all exist, 1 if any missing
-	if !allExists {
-		os.Exit(1)
+	exitCode := exitOK
+	switch {
+	case agg.FirstViolation != "":
+		exitCode = exitThresholdViolation
+	case !allExists:
+		exitCode = exitMissingKeys
+	case *requireAll && agg.HealthyCount != agg.Total:
+		exitCode = exitDegraded
+	case *requireAny && agg.HealthyCount == 0:
+		exitCode = exitDegraded
+	case *minHealthy > 0 && agg.HealthyCount < *minHealthy:
+		exitCode = exitDegraded
 	}
-	os.Exit(0)
+	os.Exit(exitCode)
+}
+
+// aggregate evaluates thresholds against every target's result and summarizes
+// the outcome. A target only counts as healthy if its keys were all present
+// and it satisfies every threshold.
+func aggregate(results map[string]HealthData, thresholds []Threshold) *Aggregate {
+	agg := &Aggregate{Total: len(results)}
+
+	targets := make([]string, 0, len(results))
+	for key := range results {
+		targets = append(targets, key)
+	}
+	sort.Strings(targets)
+
+	for _, key := range targets {
+		data := results[key]
+		healthy := data.Exists
+
+		if healthy {
+			for _, t := range thresholds {
+				ok, err := t.eval(data)
+				if err == nil && ok {
+					continue
+				}
+				healthy = false
+				if agg.FirstViolation == "" {
+					agg.FirstViolation = fmt.Sprintf("%s: %s", key, t.String())
+				}
+			}
+		}
+
+		if healthy {
+			agg.HealthyCount++
+		} else {
+			agg.FailedTargets = append(agg.FailedTargets, key)
+		}
+	}
+
+	return agg
+}
+
+// WatchEvent is one line of the newline-delimited JSON stream emitted by
+// --watch/--daemon mode. Target is the "<service>:<category>" pair that
+// changed (or, for the very first emission of each target, its initial
+// state).
+type WatchEvent struct {
+	Target string     `json:"target"`
+	Data   HealthData `json:"data"`
+}
+
+// runWatch keeps the Redis connection open and streams a WatchEvent per
+// line on stdout every time a target's health keys change, instead of the
+// one-shot fetch+exit behavior used by the rest of main. It returns once
+// the process receives SIGINT/SIGTERM.
+//
+// Updates are driven by keyspace notifications (PSUBSCRIBE on
+// __keyspace@<db>__:<service>:__healthpool__<category>_*, db being 0 for a
+// cluster connection and redisDB otherwise - see buildRedisClient), which
+// requires `notify-keyspace-events KEA` on the Redis server; runWatch
+// attempts to set this itself as a best effort. When interval is non-zero,
+// targets are additionally re-emitted on that cadence, which also covers
+// deployments where keyspace notifications are disabled or unavailable
+// (e.g. managed Redis offerings that reject CONFIG SET).
+//
+// mode is only consulted to pick the keyspace-notification DB number; the
+// fetch itself always goes through fetchTargets regardless of connection
+// mode, since client.Pipeline() already redirects per node when talking to
+// a Cluster.
+func runWatch(client redis.UniversalClient, targets []ServiceCategory, checks []HealthCheck, mode string, interval time.Duration) {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	// Best effort: ignore failures, since many managed Redis deployments
+	// disallow CONFIG SET and expect it to be configured out of band.
+	_ = client.ConfigSet(ctx, "notify-keyspace-events", keyspaceNotifyConfig).Err()
+
+	// Redis Cluster only supports DB 0 (see buildRedisClient), so the
+	// keyspace-notification channel for a cluster connection is always on
+	// db 0 regardless of redisDB.
+	db := redisDB
+	if mode == redisModeCluster {
+		db = 0
+	}
+
+	byChannel := make(map[string]ServiceCategory, len(targets))
+	patterns := make([]string, 0, len(targets))
+	for _, target := range targets {
+		pattern := fmt.Sprintf("__keyspace@%d__:%s:__healthpool__%s_*", db, target.Service, target.Category)
+		patterns = append(patterns, pattern)
+		byChannel[pattern] = target
+	}
+
+	pubsub := client.PSubscribe(ctx, patterns...)
+	defer pubsub.Close()
+
+	emit := func(target ServiceCategory) {
+		key := targetKey(target)
+		data := fetchTargets(ctx, client, checks, []ServiceCategory{target}, 1, false)[key]
+		encodeAndPrint(WatchEvent{Target: key, Data: data})
+	}
+
+	// Emit the current state of every target once at startup so consumers
+	// don't have to wait for the first change.
+	for _, target := range targets {
+		emit(target)
+	}
+
+	var ticker *time.Ticker
+	var tick <-chan time.Time
+	if interval > 0 {
+		ticker = time.NewTicker(interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	msgCh := pubsub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg, ok := <-msgCh:
+			if !ok {
+				return
+			}
+			target, known := byChannel[msg.Pattern]
+			if !known {
+				continue
+			}
+			emit(target)
+		case <-tick:
+			for _, target := range targets {
+				emit(target)
+			}
+		}
+	}
+}
+
+// encodeAndPrint writes v as a single line of compact JSON, matching the
+// newline-delimited JSON format expected by --watch consumers.
+func encodeAndPrint(v any) {
+	output, err := json.Marshal(v)
+	if err != nil {
+		return
+	}
+	fmt.Println(string(output))
 }
 
 func parseArguments(args []string) ([]ServiceCategory, error) {
@@ -169,35 +428,84 @@ func parseArguments(args []string) ([]ServiceCategory, error) {
 	return targets, nil
 }
 
-func fetchHealthData(ctx context.Context, client *redis.Client, service, category string) HealthData {
-	data := HealthData{Exists: true}
-	var missingKeys []string
+// fetchTargets fetches health data for every target, running up to
+// concurrency fetches in parallel. Each individual target fetch is itself
+// pipelined (see fetchHealthData), so the whole set of targets completes
+// in roughly concurrency-bounded RTT batches rather than one RTT per key
+// per target.
+func fetchTargets(ctx context.Context, client redis.UniversalClient, checks []HealthCheck, targets []ServiceCategory, concurrency int, verbose bool) map[string]HealthData {
+	results := make(map[string]HealthData, len(targets))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, concurrency)
 
-	// Define all keys to fetch
-	keys := map[string]**int{
-		"beats":          &data.Beats,
-		"cap":            &data.Cap,
-		"depletion_rate": &data.DepletionRate,
-		"init":           &data.Init,
-		"replenish_rate": &data.ReplenishRate,
-		"running":        &data.Running,
-		"exit":           &data.Exit,
-		"health":         &data.Health,
+	for _, target := range targets {
+		target := target
+		wg.Add(1)
+		sem <- struct{}{}
+
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			start := time.Now()
+			data := fetchHealthData(ctx, client, checks, target.Service, target.Category)
+			if verbose {
+				latencyMS := time.Since(start).Seconds() * 1000
+				data.LatencyMS = &latencyMS
+			}
+
+			key := fmt.Sprintf("%s:%s", target.Service, target.Category)
+			mu.Lock()
+			results[key] = data
+			mu.Unlock()
+		}()
 	}
 
-	// Fetch each key
-	for keyName, targetPtr := range keys {
-		redisKey := fmt.Sprintf("%s:__healthpool__%s_%s", service, category, keyName)
-		val, err := client.Get(ctx, redisKey).Int()
+	wg.Wait()
+	return results
+}
+
+// fetchHealthData runs every check for a single service:category target in
+// one Redis round trip via a pipeline, instead of one GET per check.
+func fetchHealthData(ctx context.Context, client redis.UniversalClient, checks []HealthCheck, service, category string) HealthData {
+	data := HealthData{Exists: true, Checks: make(map[string]json.RawMessage, len(checks))}
+
+	pipe := client.Pipeline()
+	cmds := make([]*redis.StringCmd, len(checks))
+	for i, check := range checks {
+		cmds[i] = pipe.Get(ctx, check.RedisKey(service, category))
+	}
+	// Errors are inspected per-command below; redis.Nil on individual keys
+	// is expected and not a pipeline failure.
+	_, _ = pipe.Exec(ctx)
 
+	var missingKeys []string
+	for i, check := range checks {
+		val, err := cmds[i].Result()
 		if err == redis.Nil {
-			missingKeys = append(missingKeys, keyName)
-		} else if err == nil {
-			*targetPtr = &val
+			missingKeys = append(missingKeys, check.Name())
+			continue
+		}
+		if err != nil {
+			missingKeys = append(missingKeys, check.Name())
+			continue
 		}
+
+		decoded, err := check.Decode(val)
+		if err != nil {
+			missingKeys = append(missingKeys, check.Name())
+			continue
+		}
+
+		encoded, err := marshalCheckValue(decoded)
+		if err != nil {
+			continue
+		}
+		data.Checks[check.Name()] = encoded
 	}
 
-	// Mark as not existing if any required key is missing
+	// Mark as not existing if any check's key is missing
 	if len(missingKeys) > 0 {
 		data.Exists = false
 		data.MissingKeys = missingKeys
@@ -218,12 +526,8 @@ func loadConfig(configPath string) (*Config, error) {
 		return nil, fmt.Errorf("failed to parse JSON config: %w", err)
 	}
 
-	// Validate required fields
-	if config.Redis.Host == "" {
-		return nil, fmt.Errorf("redis.host is required")
-	}
-	if config.Redis.Port == 0 {
-		return nil, fmt.Errorf("redis.port is required")
+	if err := config.Redis.validate(); err != nil {
+		return nil, err
 	}
 	if config.Redis.SecretsDir == "" {
 		return nil, fmt.Errorf("redis.secrets_dir is required")
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Redis connection modes for RedisConfig.Mode.
+const (
+	redisModeStandalone = "standalone"
+	redisModeSentinel   = "sentinel"
+	redisModeCluster    = "cluster"
+)
+
+// RedisConfig is the "redis" section of the config file. Mode selects
+// between a single-endpoint connection (the original behavior), Sentinel
+// (a master_name plus the Sentinel addrs), and Cluster (a list of seed
+// node addrs) - all three are connected via redis.NewUniversalClient.
+type RedisConfig struct {
+	Mode       string     `json:"mode,omitempty"`
+	Host       string     `json:"host,omitempty"`
+	Port       int        `json:"port,omitempty"`
+	Addrs      []string   `json:"addrs,omitempty"`
+	MasterName string     `json:"master_name,omitempty"`
+	SecretsDir string     `json:"secrets_dir"`
+	TLS        *TLSConfig `json:"tls,omitempty"`
+}
+
+// TLSConfig configures TLS for the Redis connection. CAFile/CertFile/KeyFile
+// are all optional: set CAFile alone to verify the server against a private
+// CA, or CertFile+KeyFile as well for mutual TLS.
+type TLSConfig struct {
+	CAFile             string `json:"ca_file,omitempty"`
+	CertFile           string `json:"cert_file,omitempty"`
+	KeyFile            string `json:"key_file,omitempty"`
+	InsecureSkipVerify bool   `json:"insecure_skip_verify,omitempty"`
+}
+
+// validate checks that the fields required by Mode are present, defaulting
+// Mode to "standalone" if unset.
+func (c *RedisConfig) validate() error {
+	if c.Mode == "" {
+		c.Mode = redisModeStandalone
+	}
+
+	switch c.Mode {
+	case redisModeStandalone:
+		if len(c.Addrs) == 0 && (c.Host == "" || c.Port == 0) {
+			return fmt.Errorf("redis.host and redis.port (or redis.addrs) are required in standalone mode")
+		}
+		// redis.NewUniversalClient infers a *ClusterClient from len(Addrs) >
+		// 1, so a standalone config listing more than one address would
+		// silently connect as cluster instead of the single endpoint
+		// "standalone" promises.
+		if len(c.Addrs) > 1 {
+			return fmt.Errorf("redis.addrs must list exactly one address in standalone mode (use mode \"cluster\" for multiple)")
+		}
+	case redisModeSentinel:
+		if c.MasterName == "" {
+			return fmt.Errorf("redis.master_name is required in sentinel mode")
+		}
+		if len(c.Addrs) == 0 {
+			return fmt.Errorf("redis.addrs (Sentinel addresses) is required in sentinel mode")
+		}
+	case redisModeCluster:
+		// redis.NewUniversalClient only builds a *ClusterClient when given
+		// more than one address; a single seed node would silently fall
+		// back to a standalone client and misbehave on any key owned by a
+		// different node, so require at least 2 here rather than at
+		// connect time.
+		if len(c.Addrs) < 2 {
+			return fmt.Errorf("redis.addrs (cluster seed nodes) must list at least 2 addresses in cluster mode")
+		}
+	default:
+		return fmt.Errorf("unknown redis.mode %q", c.Mode)
+	}
+
+	return nil
+}
+
+// addrs returns the addresses to connect to, falling back to host:port for
+// standalone configs that haven't been migrated to the addrs list.
+func (c *RedisConfig) addrs() []string {
+	if len(c.Addrs) > 0 {
+		return c.Addrs
+	}
+	return []string{fmt.Sprintf("%s:%d", c.Host, c.Port)}
+}
+
+// buildRedisClient connects according to config.Mode, returning a
+// redis.UniversalClient so the rest of the program doesn't need to care
+// whether it's talking to a single node, a Sentinel-managed master, or a
+// Cluster.
+func buildRedisClient(config *RedisConfig, username, password string) (redis.UniversalClient, error) {
+	tlsConfig, err := buildTLSConfig(config.TLS)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := &redis.UniversalOptions{
+		Addrs:     config.addrs(),
+		Username:  username,
+		Password:  password,
+		// Redis Cluster only supports DB 0; UniversalOptions' DB is ignored
+		// by the resulting ClusterClient, so the health pool must live in
+		// DB 0 on a cluster deployment.
+		DB:        redisDB,
+		TLSConfig: tlsConfig,
+	}
+
+	if config.Mode == redisModeSentinel {
+		opts.MasterName = config.MasterName
+	}
+
+	return redis.NewUniversalClient(opts), nil
+}
+
+// buildTLSConfig builds a *tls.Config from a TLS section, or returns nil if
+// none is configured (i.e. a plain TCP connection).
+func buildTLSConfig(config *TLSConfig) (*tls.Config, error) {
+	if config == nil {
+		return nil, nil
+	}
+
+	tlsConfig := &tls.Config{InsecureSkipVerify: config.InsecureSkipVerify}
+
+	if config.CAFile != "" {
+		caCert, err := os.ReadFile(config.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read redis.tls.ca_file %s: %w", config.CAFile, err)
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caCert) {
+			return nil, fmt.Errorf("redis.tls.ca_file %s contains no usable certificates", config.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if config.CertFile != "" || config.KeyFile != "" {
+		if config.CertFile == "" || config.KeyFile == "" {
+			return nil, fmt.Errorf("redis.tls.cert_file and redis.tls.key_file must be set together")
+		}
+
+		cert, err := tls.LoadX509KeyPair(config.CertFile, config.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load redis.tls client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
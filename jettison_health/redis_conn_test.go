@@ -0,0 +1,113 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRedisConfigValidate(t *testing.T) {
+	cases := []struct {
+		name    string
+		config  RedisConfig
+		wantErr bool
+	}{
+		{"standalone host and port", RedisConfig{Host: "localhost", Port: 6379}, false},
+		{"standalone addrs", RedisConfig{Mode: redisModeStandalone, Addrs: []string{"localhost:6379"}}, false},
+		{"standalone missing everything", RedisConfig{Mode: redisModeStandalone}, true},
+		{"standalone rejects multiple addrs", RedisConfig{Mode: redisModeStandalone, Addrs: []string{"localhost:6379", "localhost:6380"}}, true},
+		{"sentinel", RedisConfig{Mode: redisModeSentinel, MasterName: "mymaster", Addrs: []string{"localhost:26379"}}, false},
+		{"sentinel missing master name", RedisConfig{Mode: redisModeSentinel, Addrs: []string{"localhost:26379"}}, true},
+		{"sentinel missing addrs", RedisConfig{Mode: redisModeSentinel, MasterName: "mymaster"}, true},
+		{"cluster with two seeds", RedisConfig{Mode: redisModeCluster, Addrs: []string{"localhost:7000", "localhost:7001"}}, false},
+		{"cluster with one seed", RedisConfig{Mode: redisModeCluster, Addrs: []string{"localhost:7000"}}, true},
+		{"cluster with no seeds", RedisConfig{Mode: redisModeCluster}, true},
+		{"unknown mode", RedisConfig{Mode: "bogus"}, true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.config.validate()
+			if (err != nil) != tc.wantErr {
+				t.Errorf("validate() error = %v, wantErr %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestRedisConfigValidateDefaultsMode(t *testing.T) {
+	config := RedisConfig{Host: "localhost", Port: 6379}
+	if err := config.validate(); err != nil {
+		t.Fatalf("validate() = %v, want nil", err)
+	}
+	if config.Mode != redisModeStandalone {
+		t.Errorf("Mode = %q, want %q", config.Mode, redisModeStandalone)
+	}
+}
+
+func TestRedisConfigAddrs(t *testing.T) {
+	withAddrs := RedisConfig{Addrs: []string{"a:1", "b:2"}}
+	if got := withAddrs.addrs(); len(got) != 2 || got[0] != "a:1" {
+		t.Errorf("addrs() = %v, want [a:1 b:2]", got)
+	}
+
+	hostPort := RedisConfig{Host: "localhost", Port: 6379}
+	if got := hostPort.addrs(); len(got) != 1 || got[0] != "localhost:6379" {
+		t.Errorf("addrs() = %v, want [localhost:6379]", got)
+	}
+}
+
+func TestBuildTLSConfigNil(t *testing.T) {
+	tlsConfig, err := buildTLSConfig(nil)
+	if err != nil {
+		t.Fatalf("buildTLSConfig(nil) error = %v", err)
+	}
+	if tlsConfig != nil {
+		t.Errorf("buildTLSConfig(nil) = %v, want nil", tlsConfig)
+	}
+}
+
+func TestBuildTLSConfigCertKeyMismatch(t *testing.T) {
+	_, err := buildTLSConfig(&TLSConfig{CertFile: "cert.pem"})
+	if err == nil {
+		t.Fatal("expected an error when cert_file is set without key_file")
+	}
+}
+
+func TestBuildTLSConfigMissingCAFile(t *testing.T) {
+	_, err := buildTLSConfig(&TLSConfig{CAFile: filepath.Join(t.TempDir(), "missing-ca.pem")})
+	if err == nil {
+		t.Fatal("expected an error for a missing ca_file")
+	}
+}
+
+func TestBuildTLSConfigCAFile(t *testing.T) {
+	caPath := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(caPath, []byte(testCACert), 0o600); err != nil {
+		t.Fatalf("failed to write test CA file: %v", err)
+	}
+
+	tlsConfig, err := buildTLSConfig(&TLSConfig{CAFile: caPath, InsecureSkipVerify: true})
+	if err != nil {
+		t.Fatalf("buildTLSConfig() error = %v", err)
+	}
+	if tlsConfig.RootCAs == nil {
+		t.Error("RootCAs not populated from ca_file")
+	}
+	if !tlsConfig.InsecureSkipVerify {
+		t.Error("InsecureSkipVerify not propagated")
+	}
+}
+
+// testCACert is a throwaway self-signed certificate used only to exercise
+// buildTLSConfig's PEM parsing; it is not used to make a real connection.
+const testCACert = `-----BEGIN CERTIFICATE-----
+MIIBejCCAR+gAwIBAgIUUkzF0aN6IzaP8kou5M0UA++kkhowCgYIKoZIzj0EAwIw
+EjEQMA4GA1UECgwHVGVzdCBDQTAeFw0yNjA3MjcwMzMyMzBaFw0zNjA3MjQwMzMy
+MzBaMBIxEDAOBgNVBAoMB1Rlc3QgQ0EwWTATBgcqhkjOPQIBBggqhkjOPQMBBwNC
+AAR//VW62RlMPEhoqQQF79JJ2XwlVZl7MxfLabjHmBg9Sm5StHNAJtR16GbrRrgE
+6jCjjWwfjcw9uJNaJeg1abZmo1MwUTAdBgNVHQ4EFgQUwAx1Z6csmA4t6qR3WIQh
+pdfyDYIwHwYDVR0jBBgwFoAUwAx1Z6csmA4t6qR3WIQhpdfyDYIwDwYDVR0TAQH/
+BAUwAwEB/zAKBggqhkjOPQQDAgNJADBGAiEAnCUf6/SOx0531TQKvHixq0xnctj5
+okyyD+xOTeDFp40CIQDkZPPgxcyncKPHzhcHZDKz5/oWWjsLKt6SBbg/jQUCqQ==
+-----END CERTIFICATE-----`
@@ -0,0 +1,243 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultServeListenAddr is used when neither --listen nor the config's
+// serve.listen_addr is set.
+const defaultServeListenAddr = ":9090"
+
+// Duration wraps time.Duration so it can be parsed from a JSON duration
+// string (e.g. "30s") in config files, the way flag.Duration parses it on
+// the command line.
+type Duration time.Duration
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return fmt.Errorf("invalid duration %q: %w", s, err)
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// runServe implements the "serve" subcommand: an HTTP server exposing
+// /metrics in Prometheus text format and /healthz for liveness, scraping
+// the service:category targets listed in the config file's "serve.targets"
+// (rather than CLI args, since a scrape target list is long-lived
+// configuration).
+func runServe(args []string) {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	configPath := fs.String("config", "", "Path to configuration file")
+	listen := fs.String("listen", "", "Address to listen on, overrides config serve.listen_addr (default "+defaultServeListenAddr+")")
+	concurrency := fs.Int("concurrency", defaultConcurrency, "Maximum number of targets fetched concurrently per scrape")
+	timeout := fs.Duration("timeout", defaultRedisTimeout, "Redis operation timeout per scrape")
+	fs.Parse(args)
+
+	if *concurrency <= 0 {
+		*concurrency = defaultConcurrency
+	}
+
+	if *configPath == "" {
+		printError("Configuration required", "Usage: jettison_health serve --config <config.json>", nil)
+		os.Exit(1)
+	}
+
+	config, err := loadConfig(*configPath)
+	if err != nil {
+		printError("Configuration error", err.Error(), nil)
+		os.Exit(1)
+	}
+
+	if len(config.Serve.Targets) == 0 {
+		printError("No targets configured", "config's serve.targets must list at least one {\"service\":...,\"category\":...} pair", nil)
+		os.Exit(1)
+	}
+
+	checks, err := buildChecks(config)
+	if err != nil {
+		printError("Invalid check configuration", err.Error(), nil)
+		os.Exit(1)
+	}
+
+	username, password, err := loadRedisCredentials(config)
+	if err != nil {
+		printError("Credential loading failed", err.Error(), nil)
+		os.Exit(1)
+	}
+
+	client, err := buildRedisClient(&config.Redis, username, password)
+	if err != nil {
+		printError("Redis client configuration failed", err.Error(), nil)
+		os.Exit(1)
+	}
+	defer client.Close()
+
+	listenAddr := config.Serve.ListenAddr
+	if *listen != "" {
+		listenAddr = *listen
+	}
+	if listenAddr == "" {
+		listenAddr = defaultServeListenAddr
+	}
+
+	cache := &scrapeCache{ttl: time.Duration(config.Serve.ScrapeCacheTTL)}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		fmt.Fprintln(w, "ok")
+	})
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		body := cache.get(func() []byte {
+			scrapeCtx, cancel := context.WithTimeout(context.Background(), *timeout)
+			defer cancel()
+			results := fetchTargets(scrapeCtx, client, checks, config.Serve.Targets, *concurrency, false)
+			return []byte(renderMetrics(results, config.Serve.Targets))
+		})
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		w.Write(body)
+	})
+
+	server := &http.Server{Addr: listenAddr, Handler: mux}
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		printError("Server error", err.Error(), nil)
+		os.Exit(1)
+	}
+}
+
+// scrapeCache memoizes the last /metrics response body for ttl, so that a
+// high scrape frequency doesn't translate 1:1 into Redis load. A ttl <= 0
+// disables caching: every scrape refetches.
+type scrapeCache struct {
+	mu        sync.Mutex
+	ttl       time.Duration
+	expiresAt time.Time
+	body      []byte
+}
+
+func (c *scrapeCache) get(refresh func() []byte) []byte {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.ttl <= 0 || time.Now().After(c.expiresAt) {
+		c.body = refresh()
+		c.expiresAt = time.Now().Add(c.ttl)
+	}
+	return c.body
+}
+
+// renderMetrics renders results as Prometheus/OpenMetrics text exposition
+// format: a jettison_health_target_exists gauge, one gauge per numeric
+// check (named jettison_health_<check>), and a jettison_health_missing_key
+// info metric per currently-missing key.
+func renderMetrics(results map[string]HealthData, targets []ServiceCategory) string {
+	var b strings.Builder
+
+	writeHeader := func(name, help, typ string) {
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+	}
+
+	writeHeader("jettison_health_target_exists", "Whether all configured checks had a value for this target (1) or not (0).", "gauge")
+	for _, target := range sortedTargets(targets) {
+		data := results[targetKey(target)]
+		exists := 0
+		if data.Exists {
+			exists = 1
+		}
+		fmt.Fprintf(&b, "jettison_health_target_exists{service=%q,category=%q} %d\n", target.Service, target.Category, exists)
+	}
+
+	for _, name := range checkNames(results) {
+		metric := "jettison_health_" + name
+		writeHeader(metric, fmt.Sprintf("Value of the %q health-pool check.", name), "gauge")
+		for _, target := range sortedTargets(targets) {
+			data := results[targetKey(target)]
+			val, ok := numericCheckValue(data.Checks[name])
+			if !ok {
+				continue
+			}
+			fmt.Fprintf(&b, "%s{service=%q,category=%q} %v\n", metric, target.Service, target.Category, val)
+		}
+	}
+
+	writeHeader("jettison_health_missing_key", "Info metric: one series per target+check currently missing its Redis key.", "gauge")
+	for _, target := range sortedTargets(targets) {
+		data := results[targetKey(target)]
+		for _, key := range data.MissingKeys {
+			fmt.Fprintf(&b, "jettison_health_missing_key{service=%q,category=%q,key=%q} 1\n", target.Service, target.Category, key)
+		}
+	}
+
+	return b.String()
+}
+
+func targetKey(target ServiceCategory) string {
+	return fmt.Sprintf("%s:%s", target.Service, target.Category)
+}
+
+func sortedTargets(targets []ServiceCategory) []ServiceCategory {
+	sorted := make([]ServiceCategory, len(targets))
+	copy(sorted, targets)
+	sort.Slice(sorted, func(i, j int) bool { return targetKey(sorted[i]) < targetKey(sorted[j]) })
+	return sorted
+}
+
+// checkNames returns the sorted set of check names present across results,
+// so gauges are emitted in a stable order across scrapes.
+func checkNames(results map[string]HealthData) []string {
+	seen := make(map[string]bool)
+	for _, data := range results {
+		for name := range data.Checks {
+			seen[name] = true
+		}
+	}
+
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// numericCheckValue decodes a check's raw JSON value as a float64 gauge
+// reading. Boolean checks (e.g. a "degraded" flag) report as 0/1; string
+// checks (e.g. "last_error") have no numeric representation and are
+// skipped.
+func numericCheckValue(raw json.RawMessage) (float64, bool) {
+	if raw == nil {
+		return 0, false
+	}
+
+	var f float64
+	if err := json.Unmarshal(raw, &f); err == nil {
+		return f, true
+	}
+
+	var b bool
+	if err := json.Unmarshal(raw, &b); err == nil {
+		if b {
+			return 1, true
+		}
+		return 0, true
+	}
+
+	return 0, false
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRenderMetrics(t *testing.T) {
+	targets := []ServiceCategory{
+		{Service: "svc-b", Category: "cat1"},
+		{Service: "svc-a", Category: "cat1"},
+	}
+	results := map[string]HealthData{
+		"svc-a:cat1": {
+			Exists: true,
+			Checks: map[string]json.RawMessage{
+				"beats":    json.RawMessage(`12345`),
+				"degraded": json.RawMessage(`true`),
+			},
+		},
+		"svc-b:cat1": {
+			Exists:      false,
+			MissingKeys: []string{"beats"},
+			Checks:      map[string]json.RawMessage{},
+		},
+	}
+
+	out := renderMetrics(results, targets)
+
+	wantLines := []string{
+		`jettison_health_target_exists{service="svc-a",category="cat1"} 1`,
+		`jettison_health_target_exists{service="svc-b",category="cat1"} 0`,
+		`jettison_health_beats{service="svc-a",category="cat1"} 12345`,
+		`jettison_health_degraded{service="svc-a",category="cat1"} 1`,
+		`jettison_health_missing_key{service="svc-b",category="cat1",key="beats"} 1`,
+	}
+	for _, want := range wantLines {
+		if !strings.Contains(out, want) {
+			t.Errorf("renderMetrics() output missing line %q\nfull output:\n%s", want, out)
+		}
+	}
+
+	// svc-b has no "beats" check result, so it must not get a bogus gauge line.
+	if strings.Contains(out, `jettison_health_beats{service="svc-b"`) {
+		t.Errorf("renderMetrics() emitted a beats gauge for svc-b, which has no beats value")
+	}
+}
+
+func TestNumericCheckValue(t *testing.T) {
+	cases := []struct {
+		name    string
+		raw     json.RawMessage
+		want    float64
+		wantOk  bool
+	}{
+		{"nil", nil, 0, false},
+		{"int", json.RawMessage(`42`), 42, true},
+		{"float", json.RawMessage(`3.5`), 3.5, true},
+		{"true", json.RawMessage(`true`), 1, true},
+		{"false", json.RawMessage(`false`), 0, true},
+		{"string", json.RawMessage(`"boom"`), 0, false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, ok := numericCheckValue(tc.raw)
+			if ok != tc.wantOk || got != tc.want {
+				t.Errorf("numericCheckValue(%s) = (%v, %v), want (%v, %v)", tc.raw, got, ok, tc.want, tc.wantOk)
+			}
+		})
+	}
+}
+
+func TestScrapeCacheReusesWithinTTL(t *testing.T) {
+	cache := &scrapeCache{ttl: time.Hour}
+
+	var calls int32
+	refresh := func() []byte {
+		atomic.AddInt32(&calls, 1)
+		return []byte("body")
+	}
+
+	cache.get(refresh)
+	cache.get(refresh)
+	cache.get(refresh)
+
+	if got := atomic.LoadInt32(&calls); got != 1 {
+		t.Errorf("refresh called %d times within TTL, want 1", got)
+	}
+}
+
+func TestScrapeCacheRefetchesAfterExpiry(t *testing.T) {
+	cache := &scrapeCache{ttl: time.Millisecond}
+
+	var calls int32
+	refresh := func() []byte {
+		atomic.AddInt32(&calls, 1)
+		return []byte("body")
+	}
+
+	cache.get(refresh)
+	time.Sleep(5 * time.Millisecond)
+	cache.get(refresh)
+
+	if got := atomic.LoadInt32(&calls); got != 2 {
+		t.Errorf("refresh called %d times across expiry, want 2", got)
+	}
+}
+
+func TestScrapeCacheDisabledRefetchesEveryCall(t *testing.T) {
+	cache := &scrapeCache{ttl: 0}
+
+	var calls int32
+	refresh := func() []byte {
+		atomic.AddInt32(&calls, 1)
+		return []byte("body")
+	}
+
+	cache.get(refresh)
+	cache.get(refresh)
+
+	if got := atomic.LoadInt32(&calls); got != 2 {
+		t.Errorf("refresh called %d times with ttl<=0, want 2", got)
+	}
+}
+
+func TestSortedTargets(t *testing.T) {
+	targets := []ServiceCategory{
+		{Service: "z", Category: "a"},
+		{Service: "a", Category: "z"},
+		{Service: "a", Category: "a"},
+	}
+
+	sorted := sortedTargets(targets)
+	if len(sorted) != len(targets) {
+		t.Fatalf("len(sorted) = %d, want %d", len(sorted), len(targets))
+	}
+	for i := 1; i < len(sorted); i++ {
+		if targetKey(sorted[i-1]) > targetKey(sorted[i]) {
+			t.Errorf("sortedTargets() not sorted: %v before %v", sorted[i-1], sorted[i])
+		}
+	}
+
+	// The input slice itself must not be mutated.
+	if targets[0].Service != "z" {
+		t.Errorf("sortedTargets() mutated its input")
+	}
+}
+
+func TestCheckNames(t *testing.T) {
+	results := map[string]HealthData{
+		"a": {Checks: map[string]json.RawMessage{"beats": nil, "cap": nil}},
+		"b": {Checks: map[string]json.RawMessage{"cap": nil, "zzz": nil}},
+	}
+
+	names := checkNames(results)
+	want := []string{"beats", "cap", "zzz"}
+	if len(names) != len(want) {
+		t.Fatalf("checkNames() = %v, want %v", names, want)
+	}
+	for i, name := range names {
+		if name != want[i] {
+			t.Errorf("checkNames()[%d] = %q, want %q", i, name, want[i])
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// thresholdOps lists supported comparison operators, longest first so that
+// e.g. ">=" is matched before ">".
+var thresholdOps = []string{">=", "<=", "==", "!=", ">", "<"}
+
+// Threshold is a single "<check><op><value>" predicate parsed from
+// --threshold, e.g. "health>=1" or "running==1".
+type Threshold struct {
+	Check string
+	Op    string
+	Value float64
+}
+
+func (t Threshold) String() string {
+	return fmt.Sprintf("%s%s%v", t.Check, t.Op, t.Value)
+}
+
+// eval reports whether data's check named t.Check satisfies the predicate.
+// It errors if the check is missing from data or isn't numeric.
+func (t Threshold) eval(data HealthData) (bool, error) {
+	raw, ok := data.Checks[t.Check]
+	if !ok {
+		return false, fmt.Errorf("check %q not present in target", t.Check)
+	}
+
+	var val float64
+	if err := json.Unmarshal(raw, &val); err != nil {
+		return false, fmt.Errorf("check %q is not numeric", t.Check)
+	}
+
+	switch t.Op {
+	case ">=":
+		return val >= t.Value, nil
+	case "<=":
+		return val <= t.Value, nil
+	case "==":
+		return val == t.Value, nil
+	case "!=":
+		return val != t.Value, nil
+	case ">":
+		return val > t.Value, nil
+	case "<":
+		return val < t.Value, nil
+	default:
+		return false, fmt.Errorf("unsupported operator %q", t.Op)
+	}
+}
+
+// parseThresholds parses a comma-separated --threshold spec, e.g.
+// "health>=1,running==1". An empty spec returns no thresholds and no error.
+func parseThresholds(spec string) ([]Threshold, error) {
+	if spec == "" {
+		return nil, nil
+	}
+
+	parts := strings.Split(spec, ",")
+	thresholds := make([]Threshold, 0, len(parts))
+	for _, part := range parts {
+		t, err := parseThreshold(strings.TrimSpace(part))
+		if err != nil {
+			return nil, err
+		}
+		thresholds = append(thresholds, t)
+	}
+
+	return thresholds, nil
+}
+
+func parseThreshold(expr string) (Threshold, error) {
+	for _, op := range thresholdOps {
+		idx := strings.Index(expr, op)
+		if idx <= 0 {
+			continue
+		}
+
+		check := strings.TrimSpace(expr[:idx])
+		valStr := strings.TrimSpace(expr[idx+len(op):])
+		value, err := strconv.ParseFloat(valStr, 64)
+		if err != nil {
+			return Threshold{}, fmt.Errorf("invalid threshold %q: %w", expr, err)
+		}
+
+		return Threshold{Check: check, Op: op, Value: value}, nil
+	}
+
+	return Threshold{}, fmt.Errorf("invalid threshold %q: expected <check><op><value>", expr)
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseThreshold(t *testing.T) {
+	cases := []struct {
+		expr    string
+		want    Threshold
+		wantErr bool
+	}{
+		{"health>=1", Threshold{Check: "health", Op: ">=", Value: 1}, false},
+		{"running==1", Threshold{Check: "running", Op: "==", Value: 1}, false},
+		{"exit!=0", Threshold{Check: "exit", Op: "!=", Value: 0}, false},
+		{"latency_ms<200", Threshold{Check: "latency_ms", Op: "<", Value: 200}, false},
+		{"cap > 10", Threshold{Check: "cap", Op: ">", Value: 10}, false},
+		{"no-operator-here", Threshold{}, true},
+		{"health>=notanumber", Threshold{}, true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.expr, func(t *testing.T) {
+			got, err := parseThreshold(tc.expr)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("parseThreshold(%q) error = %v, wantErr %v", tc.expr, err, tc.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got != tc.want {
+				t.Errorf("parseThreshold(%q) = %+v, want %+v", tc.expr, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestParseThresholds(t *testing.T) {
+	got, err := parseThresholds("health>=1,running==1")
+	if err != nil {
+		t.Fatalf("parseThresholds() error = %v", err)
+	}
+	want := []Threshold{
+		{Check: "health", Op: ">=", Value: 1},
+		{Check: "running", Op: "==", Value: 1},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("parseThresholds() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("parseThresholds()[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+
+	empty, err := parseThresholds("")
+	if err != nil || empty != nil {
+		t.Errorf("parseThresholds(\"\") = (%v, %v), want (nil, nil)", empty, err)
+	}
+}
+
+func TestThresholdEval(t *testing.T) {
+	data := HealthData{Checks: map[string]json.RawMessage{
+		"health": json.RawMessage(`1`),
+		"name":   json.RawMessage(`"ok"`),
+	}}
+
+	cases := []struct {
+		name    string
+		t       Threshold
+		want    bool
+		wantErr bool
+	}{
+		{">= satisfied", Threshold{Check: "health", Op: ">=", Value: 1}, true, false},
+		{"> not satisfied", Threshold{Check: "health", Op: ">", Value: 1}, false, false},
+		{"missing check", Threshold{Check: "missing", Op: ">=", Value: 1}, false, true},
+		{"non-numeric check", Threshold{Check: "name", Op: ">=", Value: 1}, false, true},
+		{"unsupported operator", Threshold{Check: "health", Op: "~=", Value: 1}, false, true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := tc.t.eval(data)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("eval() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if got != tc.want {
+				t.Errorf("eval() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestAggregate(t *testing.T) {
+	results := map[string]HealthData{
+		"svc-a:cat": {Exists: true, Checks: map[string]json.RawMessage{"health": json.RawMessage(`1`)}},
+		"svc-b:cat": {Exists: true, Checks: map[string]json.RawMessage{"health": json.RawMessage(`0`)}},
+		"svc-c:cat": {Exists: false},
+	}
+
+	agg := aggregate(results, []Threshold{{Check: "health", Op: ">=", Value: 1}})
+
+	if agg.Total != 3 {
+		t.Errorf("Total = %d, want 3", agg.Total)
+	}
+	if agg.HealthyCount != 1 {
+		t.Errorf("HealthyCount = %d, want 1", agg.HealthyCount)
+	}
+	if len(agg.FailedTargets) != 2 {
+		t.Fatalf("FailedTargets = %v, want 2 entries", agg.FailedTargets)
+	}
+	if agg.FailedTargets[0] != "svc-b:cat" || agg.FailedTargets[1] != "svc-c:cat" {
+		t.Errorf("FailedTargets = %v, want [svc-b:cat svc-c:cat] (sorted)", agg.FailedTargets)
+	}
+	if agg.FirstViolation == "" {
+		t.Error("FirstViolation is empty, want the svc-b:cat threshold violation recorded")
+	}
+}
+
+func TestAggregateNoThresholds(t *testing.T) {
+	results := map[string]HealthData{
+		"svc-a:cat": {Exists: true},
+		"svc-b:cat": {Exists: false},
+	}
+
+	agg := aggregate(results, nil)
+	if agg.HealthyCount != 1 {
+		t.Errorf("HealthyCount = %d, want 1", agg.HealthyCount)
+	}
+	if agg.FirstViolation != "" {
+		t.Errorf("FirstViolation = %q, want empty with no thresholds configured", agg.FirstViolation)
+	}
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// captureStdout redirects os.Stdout for the duration of fn and returns
+// everything written to it.
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("os.Pipe() error = %v", err)
+	}
+
+	original := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = original }()
+
+	fn()
+
+	w.Close()
+	var out strings.Builder
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		out.WriteString(scanner.Text())
+		out.WriteString("\n")
+	}
+	return out.String()
+}
+
+// TestRunWatchEmitsInitialStateAndExitsOnSignal exercises runWatch against a
+// miniredis server: it should emit one WatchEvent per target on startup,
+// then return once the process receives SIGINT.
+func TestRunWatchEmitsInitialStateAndExitsOnSignal(t *testing.T) {
+	s := newTestServer(t)
+
+	target := ServiceCategory{Service: "jettison", Category: "ingest"}
+	checks, err := buildChecks(&Config{})
+	if err != nil {
+		t.Fatalf("buildChecks() error = %v", err)
+	}
+	for _, check := range checks {
+		s.Set(check.RedisKey(target.Service, target.Category), "1")
+	}
+
+	client, err := buildRedisClient(&RedisConfig{Mode: redisModeStandalone, Addrs: []string{s.Addr()}}, "", "")
+	if err != nil {
+		t.Fatalf("buildRedisClient() error = %v", err)
+	}
+	defer client.Close()
+
+	done := make(chan struct{})
+	var output string
+	go func() {
+		output = captureStdout(t, func() {
+			runWatch(client, []ServiceCategory{target}, checks, redisModeStandalone, 0)
+		})
+		close(done)
+	}()
+
+	// Give runWatch time to emit the initial state before asking it to stop.
+	time.Sleep(50 * time.Millisecond)
+	if err := syscall.Kill(syscall.Getpid(), syscall.SIGINT); err != nil {
+		t.Fatalf("failed to signal self: %v", err)
+	}
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("runWatch did not return after SIGINT")
+	}
+
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("got %d output lines, want 1: %q", len(lines), output)
+	}
+
+	var event WatchEvent
+	if err := json.Unmarshal([]byte(lines[0]), &event); err != nil {
+		t.Fatalf("failed to decode watch event: %v", err)
+	}
+	if event.Target != targetKey(target) {
+		t.Errorf("event.Target = %q, want %q", event.Target, targetKey(target))
+	}
+	if !event.Data.Exists {
+		t.Errorf("event.Data.Exists = false, missing keys: %v", event.Data.MissingKeys)
+	}
+}